@@ -2,7 +2,7 @@ package illumioapi
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +13,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var Verbose bool
@@ -62,7 +66,30 @@ type asyncResults struct {
 	} `json:"requested_by"`
 }
 
-func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers map[string]string) (APIResponse, error) {
+// ctxSleep sleeps for d, returning early with ctx.Err() if ctx is canceled
+// or its deadline expires before d elapses.
+func ctxSleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers map[string]string, retry int) (APIResponse, error) {
+	return p.httpSetupCtx(context.Background(), action, apiURL, body, async, headers, retry)
+}
+
+// httpSetupCtx is the context-aware implementation behind httpSetup. ctx
+// governs the outbound request(s) and, for async calls, the polling loop -
+// canceling it aborts an in-flight request or a long-running poll.
+func (p *PCE) httpSetupCtx(ctx context.Context, action, apiURL string, body []byte, async bool, headers map[string]string, retry int) (APIResponse, error) {
 	var asyncResults asyncResults
 
 	// Get the base URL
@@ -77,26 +104,16 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 	// Create body
 	httpBody := bytes.NewBuffer(body)
 
-	// Create HTTP client and request
-	client := &http.Client{}
+	// Reuse the PCE's cached HTTP client so connections and TLS sessions
+	// are kept alive across requests instead of being rebuilt each call.
+	client := p.HTTPClient()
 
-	// Create the http transport obect
-	httpTransport := &http.Transport{}
-	if p.DisableTLSChecking {
-		httpTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-	if p.Proxy != "" {
-		proxyUrl, err := url.Parse(p.Proxy)
-		if err != nil {
-			return APIResponse{}, err
-		}
-		httpTransport.Proxy = http.ProxyURL(proxyUrl)
-	}
-
-	// Add to the client
-	client.Transport = httpTransport
+	ctx, span := p.startSpan(ctx, action, apiURL, async, retry)
+	defer span.End()
+	p.inFlightInc()
+	defer p.inFlightDec()
 
-	req, err := http.NewRequest(action, apiURL, httpBody)
+	req, err := http.NewRequestWithContext(ctx, action, apiURL, httpBody)
 	if err != nil {
 		return APIResponse{}, err
 	}
@@ -114,14 +131,32 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 		req.Header.Set("Prefer", "respond-async")
 	}
 
+	// Throttle client-side before sending, rather than only reacting
+	// after receiving a 429.
+	if err := p.limiterFor(normalizeEndpoint(apiURL)).Wait(ctx); err != nil {
+		return APIResponse{}, err
+	}
+
 	// Make HTTP Request
 	verboseLogf("httpSetup - making %s http request to %s", req.Method, req.URL)
+	p.callRequestHook(req)
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
+		duration := time.Since(start)
+		p.logger().Error("http request failed", "method", req.Method, "url", req.URL.String(), "retry", retry, "error", err)
+		p.recordRequestError(req.Method, apiURL, duration)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return APIResponse{}, err
 	}
 	defer resp.Body.Close()
+	duration := time.Since(start)
 	verboseLogf("httpSetup - http status code: %d", resp.StatusCode)
+	p.callResponseHook(resp, duration, retry, 0)
+	p.logger().Info("http request completed", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", duration, "retry", retry, "async", async)
+	p.recordRequest(req.Method, apiURL, resp.StatusCode, duration)
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
 	// Strip base URL for async logging
 	targetResource := strings.TrimPrefix(req.URL.String(), baseURL)
@@ -133,14 +168,17 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 		for asyncResults.Status != "done" {
 			iteration++
 			verboseLogf("httpSetup - checking async results for %s - attempt %d", targetResource, iteration)
-			asyncResults, err = p.asyncPoll(baseURL, resp)
+			asyncResults, err = p.asyncPollCtx(ctx, baseURL, resp, retry, iteration)
 			if err != nil {
+				p.logger().Error("async poll failed", "resource", targetResource, "iteration", iteration, "error", err)
 				return APIResponse{}, err
 			}
 		}
 		verboseLog("httpSetup - async polling done")
+		p.recordAsyncIterations(iteration)
+		span.SetAttributes(attribute.Int("illumio.async_iterations", iteration))
 
-		finalReq, err := http.NewRequest("GET", baseURL+asyncResults.Result.Href, httpBody)
+		finalReq, err := http.NewRequestWithContext(ctx, "GET", baseURL+asyncResults.Result.Href, httpBody)
 		if err != nil {
 			return APIResponse{}, err
 		}
@@ -150,13 +188,27 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 		finalReq.Header.Set("Content-Type", "application/json")
 
 		// Make HTTP Request
+		if err := p.limiterFor(normalizeEndpoint(finalReq.URL.String())).Wait(ctx); err != nil {
+			return APIResponse{}, err
+		}
 		verboseLogf("httpSetup - making http request to download async results from %s for %s", finalReq.URL.String(), targetResource)
+		p.callRequestHook(finalReq)
+		finalStart := time.Now()
 		resp, err = client.Do(finalReq)
 		if err != nil {
+			p.logger().Error("async result download failed", "resource", targetResource, "error", err)
+			p.recordRequestError(finalReq.Method, finalReq.URL.String(), time.Since(finalStart))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			return APIResponse{}, err
 		}
 		defer resp.Body.Close()
+		finalDuration := time.Since(finalStart)
 		verboseLogf("httpSetup - http status code: %d", resp.StatusCode)
+		p.callResponseHook(resp, finalDuration, retry, iteration)
+		p.logger().Info("async result downloaded", "resource", targetResource, "status", resp.StatusCode, "duration", finalDuration, "iterations", iteration)
+		p.recordRequest(finalReq.Method, finalReq.URL.String(), resp.StatusCode, finalDuration)
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
 	}
 
@@ -172,6 +224,7 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 	response.StatusCode = resp.StatusCode
 	response.Header = resp.Header
 	response.Request = resp.Request
+	span.SetAttributes(attribute.Int("illumio.response_size", len(data)))
 
 	// Check for a 200 response code
 	if strconv.Itoa(resp.StatusCode)[0:1] != "2" {
@@ -183,28 +236,19 @@ func (p *PCE) httpSetup(action, apiURL string, body []byte, async bool, headers
 }
 
 // asyncPoll is used in async requests to check when the data is ready
-func (p *PCE) asyncPoll(baseURL string, origResp *http.Response) (asyncResults asyncResults, err error) {
+func (p *PCE) asyncPoll(baseURL string, origResp *http.Response, retry, iteration int) (asyncResults asyncResults, err error) {
+	return p.asyncPollCtx(context.Background(), baseURL, origResp, retry, iteration)
+}
 
-	// Create HTTP client and request
-	client := &http.Client{}
+// asyncPollCtx is the context-aware implementation behind asyncPoll. ctx
+// governs both the Retry-After sleep and the poll request, so a long async
+// export can be aborted without waiting it out.
+func (p *PCE) asyncPollCtx(ctx context.Context, baseURL string, origResp *http.Response, retry, iteration int) (asyncResults asyncResults, err error) {
 
-	// Create the http transport obect
-	httpTransport := &http.Transport{}
-	if p.DisableTLSChecking {
-		httpTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-	if p.Proxy != "" {
-		proxyUrl, err := url.Parse(p.Proxy)
-		if err != nil {
-			return asyncResults, err
-		}
-		httpTransport.Proxy = http.ProxyURL(proxyUrl)
-	}
-
-	// Add to the client
-	client.Transport = httpTransport
+	// Reuse the PCE's cached HTTP client
+	client := p.HTTPClient()
 
-	pollReq, err := http.NewRequest("GET", baseURL+origResp.Header.Get("Location"), nil)
+	pollReq, err := http.NewRequestWithContext(ctx, "GET", baseURL+origResp.Header.Get("Location"), nil)
 	verboseLogf("asyncPoll - pollReq.UR.String(): %s", pollReq.URL.String())
 	if err != nil {
 		return asyncResults, err
@@ -215,24 +259,39 @@ func (p *PCE) asyncPoll(baseURL string, origResp *http.Response) (asyncResults a
 	pollReq.Header.Set("Content-Type", "application/json")
 
 	// Wait for recommended time from Retry-After
-	wait, err := strconv.Atoi(origResp.Header.Get("Retry-After"))
-	verboseLogf("asyncPoll - Retry-After: %d", wait)
-	if err != nil {
-		return asyncResults, err
+	wait, ok := parseRetryAfter(origResp.Header.Get("Retry-After"))
+	if !ok {
+		return asyncResults, fmt.Errorf("async poll missing or invalid Retry-After header")
 	}
-	duration := time.Duration(wait) * time.Second
+	verboseLogf("asyncPoll - duration.Seconds(): %d", int(wait.Seconds()))
 	verboseLog("asyncPoll - sleeping for Retry-After period")
-	verboseLogf("asyncPoll - duration.Seconds(): %d", int(duration.Seconds()))
-	time.Sleep(duration)
+	if err := ctxSleep(ctx, wait); err != nil {
+		return asyncResults, err
+	}
 
 	// Check if the data is ready
+	if err := p.limiterFor(normalizeEndpoint(pollReq.URL.String())).Wait(ctx); err != nil {
+		return asyncResults, err
+	}
 	verboseLogf("asyncPoll - making http request to %s", pollReq.URL.String())
+	p.callRequestHook(pollReq)
+	span := trace.SpanFromContext(ctx)
+	start := time.Now()
 	pollResp, err := client.Do(pollReq)
 	if err != nil {
+		p.logger().Error("async poll request failed", "url", pollReq.URL.String(), "iteration", iteration, "error", err)
+		p.recordRequestError(pollReq.Method, pollReq.URL.String(), time.Since(start))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return asyncResults, err
 	}
 	defer pollResp.Body.Close()
+	pollDuration := time.Since(start)
 	verboseLogf("asyncPoll - http status code: %d", pollResp.StatusCode)
+	p.callResponseHook(pollResp, pollDuration, retry, iteration)
+	p.logger().Debug("async poll response", "status", pollResp.StatusCode, "duration", pollDuration, "iteration", iteration)
+	p.recordRequest(pollReq.Method, pollReq.URL.String(), pollResp.StatusCode, pollDuration)
+	span.SetAttributes(attribute.Int("http.status_code", pollResp.StatusCode))
 
 	// Process Response
 	data, err := io.ReadAll(pollResp.Body)
@@ -252,24 +311,39 @@ func (p *PCE) asyncPoll(baseURL string, origResp *http.Response) (asyncResults a
 // PUT and POST methods should have a body that is JSON run through the json.marshal function so it's a []byte.
 // async parameter should be set to true for any GET requests returning > 500 items.
 func (p *PCE) httpReq(action, apiURL string, body []byte, async bool, headers map[string]string) (APIResponse, error) {
+	return p.httpReqCtx(context.Background(), action, apiURL, body, async, headers)
+}
+
+// httpReqCtx is the context-aware implementation behind httpReq. It applies
+// p.RetryPolicy (falling back to DefaultRetryPolicy) to retryable status
+// codes, honoring the Retry-After header when present and backing off
+// exponentially otherwise. ctx cancellation aborts an in-flight request or
+// an in-progress retry sleep.
+func (p *PCE) httpReqCtx(ctx context.Context, action, apiURL string, body []byte, async bool, headers map[string]string) (APIResponse, error) {
+
+	policy := p.retryPolicy()
 
 	// Make initial http call
-	api, err := p.httpSetup(action, apiURL, body, async, headers)
 	retry := 0
+	api, err := p.httpSetupCtx(ctx, action, apiURL, body, async, headers, retry)
 
-	// If the status code is 429, try 3 times
-	for api.StatusCode == 429 {
-		// If we have already tried 3 times, exit
-		if retry > 6 {
-			return api, errors.New("received 6 429 errors with 30 second pauses between attempts")
+	// Retry while the response status is one the policy considers retryable
+	for policy.isRetryable(api.StatusCode) {
+		if retry >= policy.MaxAttempts {
+			p.logger().Error("giving up after repeated retryable responses", "method", action, "url", apiURL, "status", api.StatusCode, "retry", retry)
+			return api, fmt.Errorf("received %d retryable responses in a row (last status %d)", retry+1, api.StatusCode)
 		}
-		// Increment the retry counter and sleep for 30 seconds
+		retryAfter, _ := parseRetryAfter(api.Header.Get("Retry-After"))
+		d := policy.delay(retry, retryAfter)
 		retry++
-		time.Sleep(30 * time.Second)
+		p.logger().Warn("retrying request", "method", action, "url", apiURL, "status", api.StatusCode, "retry", retry, "delay", d)
+		if err := ctxSleep(ctx, d); err != nil {
+			return api, err
+		}
 		// Retry the API call
-		api, err = p.httpSetup(action, apiURL, body, async, headers)
+		api, err = p.httpSetupCtx(ctx, action, apiURL, body, async, headers, retry)
 	}
-	// Return once response code isn't 429
+	// Return once the response code isn't retryable
 	return api, err
 }
 
@@ -0,0 +1,157 @@
+package illumioapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// buildAPIURL assembles a full PCE API URL for endpoint (which must start
+// with "/"), applying queryParameters as URL query parameters.
+func (p *PCE) buildAPIURL(endpoint string, queryParameters map[string]string) string {
+	u := url.URL{Scheme: "https", Host: p.cleanFQDN(), Path: "/api/v2" + endpoint}
+	if len(queryParameters) > 0 {
+		q := url.Values{}
+		for k, v := range queryParameters {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// labelGroupAsyncThreshold mirrors the limit GetLabelGroups historically
+// used: a synchronous collection response at or above this size may have
+// been truncated by the PCE, so the request is re-run as an async export.
+const labelGroupAsyncThreshold = 500
+
+// countArrayElements reports how many elements are in the top-level JSON
+// array in body, without unmarshaling each element into a typed value.
+func countArrayElements(body string) (int, error) {
+	dec := json.NewDecoder(strings.NewReader(body))
+	if _, err := dec.Token(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// LabelGroupResult is sent on the channel returned by IterLabelGroups. Err
+// is set, with Value left zero, if decoding that element failed; the
+// iterator stops sending after an error.
+type LabelGroupResult struct {
+	Value LabelGroup
+	Err   error
+}
+
+// IterLabelGroups streams label groups from the PCE rather than loading
+// the full collection into a slice and then rebuilding a href/name/key map
+// from it the way GetLabelGroups does, which doubles peak memory for
+// tenants with very large numbers of label groups. Like GetLabelGroups, it
+// first makes a synchronous request and only falls back to an async
+// export - submitting a job, polling, and downloading the result - when
+// the synchronous response is large enough that the PCE may have
+// truncated it; small tenants pay for a single round trip, not a forced
+// async job for every call. Once the response body is in hand it's decoded
+// one element at a time with a json.Decoder rather than unmarshaled as a
+// whole slice: that avoids materializing the slice-plus-map the old
+// GetLabelGroups built, though the underlying HTTP response body itself is
+// still read fully into memory by httpReqCtx before decoding starts, so
+// this does not bound memory against the size of the raw response.
+// pStatus must be "draft" or "active". The returned channel is closed once
+// every label group has been sent or an error occurs; canceling ctx stops
+// the stream early.
+//
+// TODO(jdschmitz15/illumioapi#chunk0-5): workloads, traffic flows, and
+// rules don't have an equivalent iterator yet - this covers label groups
+// only. See TODO.md for the tracked follow-up.
+func (p *PCE) IterLabelGroups(ctx context.Context, queryParameters map[string]string, pStatus string) (<-chan LabelGroupResult, APIResponse, error) {
+	pStatus = strings.ToLower(pStatus)
+	if pStatus != "active" && pStatus != "draft" {
+		return nil, APIResponse{}, fmt.Errorf("invalid pStatus")
+	}
+
+	endpoint := "/sec_policy/" + pStatus + "/label_groups"
+	apiURL := p.buildAPIURL(endpoint, queryParameters)
+
+	api, err := p.httpReqCtx(ctx, "GET", apiURL, nil, false, nil)
+	if err != nil {
+		return nil, api, err
+	}
+
+	body := api.RespBody
+	if count, cerr := countArrayElements(body); cerr == nil && count >= labelGroupAsyncThreshold {
+		api, err = p.httpReqCtx(ctx, "GET", apiURL, nil, true, nil)
+		if err != nil {
+			return nil, api, err
+		}
+		body = api.RespBody
+	}
+
+	results := make(chan LabelGroupResult)
+	go func() {
+		defer close(results)
+		dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+		if _, err := dec.Token(); err != nil {
+			select {
+			case results <- LabelGroupResult{Err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		for dec.More() {
+			var lg LabelGroup
+			if err := dec.Decode(&lg); err != nil {
+				select {
+				case results <- LabelGroupResult{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			select {
+			case results <- LabelGroupResult{Value: lg}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results, api, nil
+}
+
+// WalkLabelGroups calls fn for every label group in the PCE, stopping and
+// returning fn's error as soon as it returns one. It's a convenience
+// wrapper around IterLabelGroups for callers who don't need channel
+// semantics.
+func (p *PCE) WalkLabelGroups(ctx context.Context, queryParameters map[string]string, pStatus string, fn func(LabelGroup) error) (APIResponse, error) {
+	// Derive a cancelable context so that returning early (e.g. fn found
+	// what it was looking for) unblocks IterLabelGroups' producer
+	// goroutine, which would otherwise sit forever on a send to results
+	// that nobody is left to receive.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results, api, err := p.IterLabelGroups(ctx, queryParameters, pStatus)
+	if err != nil {
+		return api, err
+	}
+	for r := range results {
+		if r.Err != nil {
+			return api, r.Err
+		}
+		if err := fn(r.Value); err != nil {
+			return api, err
+		}
+	}
+	return api, nil
+}
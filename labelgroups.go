@@ -1,6 +1,7 @@
 package illumioapi
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -35,8 +36,8 @@ type Usage struct {
 
 // GetLabelGroups returns a slice of label groups from the PCE. pStatus must be "draft" or "active"
 // queryParameters can be used for filtering in the form of ["parameter"]="value".
-// The first API call to the PCE does not use the async option.
-// If the slice length is >=500, it re-runs with async.
+// It's implemented on top of WalkLabelGroups and fills both p.LabelGroupsSlice
+// and p.LabelGroups with the full collection, so both are held in memory at once.
 func (p *PCE) GetLabelGroups(queryParameters map[string]string, pStatus string) (api APIResponse, err error) {
 
 	// Validate pStatus
@@ -44,17 +45,16 @@ func (p *PCE) GetLabelGroups(queryParameters map[string]string, pStatus string)
 	if pStatus != "active" && pStatus != "draft" {
 		return api, fmt.Errorf("invalid pStatus")
 	}
-	api, err = p.GetCollection("/sec_policy/"+pStatus+"/label_groups", false, queryParameters, &p.LabelGroupsSlice)
-	if len(p.LabelGroupsSlice) >= 500 {
-		p.LabelGroupsSlice = nil
-		api, err = p.GetCollection("/sec_policy/"+pStatus+"/label_groups", true, queryParameters, &p.LabelGroupsSlice)
-	}
+
+	p.LabelGroupsSlice = nil
 	p.LabelGroups = make(map[string]LabelGroup)
-	for _, lg := range p.LabelGroupsSlice {
+	api, err = p.WalkLabelGroups(context.Background(), queryParameters, pStatus, func(lg LabelGroup) error {
+		p.LabelGroupsSlice = append(p.LabelGroupsSlice, lg)
 		p.LabelGroups[lg.Href] = lg
 		p.LabelGroups[lg.Name] = lg
 		p.LabelGroups[lg.Key+lg.Name] = lg
-	}
+		return nil
+	})
 	return api, err
 }
 
@@ -0,0 +1,60 @@
+package illumioapi
+
+import (
+	"net/http"
+	"time"
+)
+
+// Logger is a structured logging interface PCE uses to emit request and
+// response telemetry. Implementations can adapt slog, logrus, go-kit/log,
+// or any other logging library by satisfying this interface and assigning
+// it to PCE.Logger.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// RequestHook is invoked immediately before an HTTP request is sent to the
+// PCE. It can be used to inspect or annotate the outgoing request.
+type RequestHook func(req *http.Request)
+
+// ResponseHook is invoked after an HTTP response is received from the PCE.
+// duration is the time elapsed since the request was sent, retry is the
+// retry attempt number (0 for the initial attempt), and asyncIteration is
+// the async polling iteration (0 when the call is not async).
+type ResponseHook func(resp *http.Response, duration time.Duration, retry, asyncIteration int)
+
+// noopLogger is the default Logger used by a PCE when none is configured.
+// It discards everything so existing callers who don't opt into structured
+// logging see no change in behavior.
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, kv ...any) {}
+func (noopLogger) Info(msg string, kv ...any)  {}
+func (noopLogger) Warn(msg string, kv ...any)  {}
+func (noopLogger) Error(msg string, kv ...any) {}
+
+// logger returns p.Logger, falling back to a no-op logger if one hasn't
+// been configured.
+func (p *PCE) logger() Logger {
+	if p.Logger == nil {
+		return noopLogger{}
+	}
+	return p.Logger
+}
+
+// callRequestHook invokes p.RequestHook if one has been configured.
+func (p *PCE) callRequestHook(req *http.Request) {
+	if p.RequestHook != nil {
+		p.RequestHook(req)
+	}
+}
+
+// callResponseHook invokes p.ResponseHook if one has been configured.
+func (p *PCE) callResponseHook(resp *http.Response, duration time.Duration, retry, asyncIteration int) {
+	if p.ResponseHook != nil {
+		p.ResponseHook(resp, duration, retry, asyncIteration)
+	}
+}
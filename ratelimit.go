@@ -0,0 +1,37 @@
+package illumioapi
+
+import (
+	"golang.org/x/time/rate"
+)
+
+// defaultRequestsPerMinute matches Illumio's documented PCE API rate limit
+// of 125 requests per minute per user.
+const defaultRequestsPerMinute = 125
+
+// defaultLimiter returns p's shared-default rate.Limiter, building it on
+// first use via p.defaultLimiterOnce. It's cached on the PCE itself,
+// rather than in a package-level registry keyed by *PCE, so two PCEs in
+// the same process (different servers, or different users) each get their
+// own 125 req/min budget instead of contending over one, and the limiter
+// is reclaimed by the garbage collector along with the PCE.
+func (p *PCE) defaultLimiter() *rate.Limiter {
+	p.defaultLimiterOnce.Do(func() {
+		p.defaultRateLimiter = rate.NewLimiter(rate.Limit(defaultRequestsPerMinute)/rate.Limit(60), defaultRequestsPerMinute)
+	})
+	return p.defaultRateLimiter
+}
+
+// limiterFor returns the rate.Limiter that should be applied to a request
+// against endpoint. An entry in p.EndpointRateLimiters keyed by the
+// normalized endpoint takes precedence over p.RateLimiter, which itself
+// falls back to a per-PCE default limiter matching the PCE's documented
+// rate limit.
+func (p *PCE) limiterFor(endpoint string) *rate.Limiter {
+	if l, ok := p.EndpointRateLimiters[endpoint]; ok {
+		return l
+	}
+	if p.RateLimiter != nil {
+		return p.RateLimiter
+	}
+	return p.defaultLimiter()
+}
@@ -0,0 +1,72 @@
+package illumioapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	d, ok := parseRetryAfter("120")
+	if !ok || d != 120*time.Second {
+		t.Fatalf("parseRetryAfter(\"120\") = %v, %v; want 120s, true", d, ok)
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	future := time.Now().Add(2 * time.Minute).UTC()
+	header := future.Format(http.TimeFormat)
+
+	d, ok := parseRetryAfter(header)
+	if !ok {
+		t.Fatalf("parseRetryAfter(%q) ok = false, want true", header)
+	}
+	if d <= 0 || d > 2*time.Minute+time.Second {
+		t.Fatalf("parseRetryAfter(%q) = %v, want roughly 2m", header, d)
+	}
+}
+
+func TestParseRetryAfterInvalid(t *testing.T) {
+	for _, h := range []string{"", "not-a-date", "-5"} {
+		if _, ok := parseRetryAfter(h); ok {
+			t.Errorf("parseRetryAfter(%q) ok = true, want false", h)
+		}
+	}
+}
+
+func TestRetryPolicyDelayUsesRetryAfter(t *testing.T) {
+	r := RetryPolicy{BaseDelay: time.Second, MaxDelay: 30 * time.Second, Multiplier: 2}
+	if d := r.delay(0, 10*time.Second); d != 10*time.Second {
+		t.Fatalf("delay() = %v, want 10s when Retry-After is set", d)
+	}
+}
+
+func TestRetryPolicyDelayCapsAtMaxDelay(t *testing.T) {
+	r := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+	if d := r.delay(10, 0); d > 5*time.Second {
+		t.Fatalf("delay(10, 0) = %v, want capped at MaxDelay of 5s", d)
+	}
+}
+
+func TestRetryPolicyDelayGrowsExponentially(t *testing.T) {
+	r := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Hour, Multiplier: 2}
+	d0, d1 := r.delay(0, 0), r.delay(1, 0)
+	if d0 != time.Second {
+		t.Fatalf("delay(0, 0) = %v, want 1s", d0)
+	}
+	if d1 != 2*time.Second {
+		t.Fatalf("delay(1, 0) = %v, want 2s", d1)
+	}
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	r := DefaultRetryPolicy()
+	for _, code := range []int{429, 502, 503, 504} {
+		if !r.isRetryable(code) {
+			t.Errorf("isRetryable(%d) = false, want true", code)
+		}
+	}
+	if r.isRetryable(200) {
+		t.Error("isRetryable(200) = true, want false")
+	}
+}
@@ -0,0 +1,149 @@
+package illumioapi
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// noopTracer is used whenever a PCE hasn't configured a Tracer, so request
+// code can start spans unconditionally without forcing an OTel SDK/exporter
+// dependency on callers who don't opt in.
+var noopTracer = tracenoop.NewTracerProvider().Tracer("")
+
+// pceMetrics holds the Prometheus collectors registered for a single
+// PCE.MetricsRegisterer.
+type pceMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	asyncIterations prometheus.Histogram
+	inFlight        prometheus.Gauge
+}
+
+var (
+	metricsMu    sync.Mutex
+	metricsCache = map[prometheus.Registerer]*pceMetrics{}
+)
+
+// metrics returns the pceMetrics registered for p.MetricsRegisterer,
+// registering them on first use. It returns nil when no MetricsRegisterer
+// has been configured, so instrumentation is skipped entirely by default.
+func (p *PCE) metrics() *pceMetrics {
+	if p.MetricsRegisterer == nil {
+		return nil
+	}
+
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if m, ok := metricsCache[p.MetricsRegisterer]; ok {
+		return m
+	}
+
+	m := &pceMetrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "illumio_api_requests_total",
+			Help: "Total number of requests made to the Illumio PCE API.",
+		}, []string{"method", "endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "illumio_api_request_duration_seconds",
+			Help: "Duration of requests made to the Illumio PCE API.",
+		}, []string{"method", "endpoint", "status"}),
+		asyncIterations: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "illumio_api_async_poll_iterations",
+			Help: "Number of polling iterations an async request took before completing.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "illumio_api_requests_in_flight",
+			Help: "Number of requests currently in flight to the Illumio PCE API.",
+		}),
+	}
+	p.MetricsRegisterer.MustRegister(m.requestsTotal, m.requestDuration, m.asyncIterations, m.inFlight)
+	metricsCache[p.MetricsRegisterer] = m
+	return m
+}
+
+// recordRequest records the Prometheus counter and histogram for a single
+// completed HTTP call, when metrics are configured.
+func (p *PCE) recordRequest(method, rawURL string, status int, duration time.Duration) {
+	m := p.metrics()
+	if m == nil {
+		return
+	}
+	endpoint := normalizeEndpoint(rawURL)
+	statusLabel := strconv.Itoa(status)
+	m.requestsTotal.WithLabelValues(method, endpoint, statusLabel).Inc()
+	m.requestDuration.WithLabelValues(method, endpoint, statusLabel).Observe(duration.Seconds())
+}
+
+// recordRequestError records a request that failed before a response was
+// received (network error, timeout, canceled context), using "error" as
+// the status label since there's no HTTP status code to report.
+func (p *PCE) recordRequestError(method, rawURL string, duration time.Duration) {
+	m := p.metrics()
+	if m == nil {
+		return
+	}
+	endpoint := normalizeEndpoint(rawURL)
+	m.requestsTotal.WithLabelValues(method, endpoint, "error").Inc()
+	m.requestDuration.WithLabelValues(method, endpoint, "error").Observe(duration.Seconds())
+}
+
+// recordAsyncIterations records how many polling iterations an async
+// request took to complete, when metrics are configured.
+func (p *PCE) recordAsyncIterations(n int) {
+	if m := p.metrics(); m != nil {
+		m.asyncIterations.Observe(float64(n))
+	}
+}
+
+// inFlightInc and inFlightDec track the in-flight request gauge, when
+// metrics are configured.
+func (p *PCE) inFlightInc() {
+	if m := p.metrics(); m != nil {
+		m.inFlight.Inc()
+	}
+}
+
+func (p *PCE) inFlightDec() {
+	if m := p.metrics(); m != nil {
+		m.inFlight.Dec()
+	}
+}
+
+// hrefSegment matches href-like path segments (UUIDs or plain numeric
+// IDs) so they can be collapsed before being used as a metric label.
+var hrefSegment = regexp.MustCompile(`/[0-9a-fA-F-]{8,}|/[0-9]+`)
+
+// normalizeEndpoint collapses the href segments of rawURL's path into a
+// stable placeholder (e.g. "/workloads/{href}") so per-resource hrefs
+// don't explode metric and span label cardinality.
+func normalizeEndpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "unknown"
+	}
+	return hrefSegment.ReplaceAllString(u.Path, "/{href}")
+}
+
+// startSpan starts a span for an outbound PCE request, falling back to a
+// no-op tracer when p.Tracer isn't configured so callers can unconditionally
+// call span.End() and set attributes.
+func (p *PCE) startSpan(ctx context.Context, method, rawURL string, async bool, retry int) (context.Context, trace.Span) {
+	tracer := p.Tracer
+	if tracer == nil {
+		tracer = noopTracer
+	}
+	return tracer.Start(ctx, "illumioapi."+method, trace.WithAttributes(
+		attribute.String("illumio.endpoint", normalizeEndpoint(rawURL)),
+		attribute.Bool("illumio.async", async),
+		attribute.Int("illumio.retry", retry),
+	))
+}
@@ -0,0 +1,17 @@
+package illumioapi
+
+import "testing"
+
+func TestNormalizeEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"https://pce.example.com/api/v2/workloads/8f14e45f-ceea-467e-adc9-15476b33d111": "/api/v2/workloads/{href}",
+		"https://pce.example.com/api/v2/sec_policy/active/label_groups/42":              "/api/v2/sec_policy/active/label_groups/{href}",
+		"https://pce.example.com/api/v2/sec_policy/active/label_groups":                 "/api/v2/sec_policy/active/label_groups",
+		"://not-a-url": "unknown",
+	}
+	for input, want := range cases {
+		if got := normalizeEndpoint(input); got != want {
+			t.Errorf("normalizeEndpoint(%q) = %q, want %q", input, got, want)
+		}
+	}
+}